@@ -0,0 +1,174 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/iterator"
+	databasepb "google.golang.org/genproto/googleapis/spanner/admin/database/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultBackupExpiry is used when Config.BackupExpiry is unset.
+const defaultBackupExpiry = 7 * 24 * time.Hour
+
+func (c *Client) instanceURL() string {
+	return fmt.Sprintf("projects/%s/instances/%s", c.config.Project, c.config.Instance)
+}
+
+func (c *Client) backupURL(backupID string) string {
+	return fmt.Sprintf("%s/backups/%s", c.instanceURL(), backupID)
+}
+
+func (c *Client) backupExpiry() time.Duration {
+	if c.config.BackupExpiry > 0 {
+		return c.config.BackupExpiry
+	}
+	return defaultBackupExpiry
+}
+
+// CreateBackup takes a backup of the database under backupID, expiring at
+// expireTime. Taking a backup before a risky ApplyDDL gives users a way
+// to roll back by restoring, which is materially safer than dropping and
+// recreating the database.
+func (c *Client) CreateBackup(ctx context.Context, backupID string, expireTime time.Time) error {
+	req := &databasepb.CreateBackupRequest{
+		Parent:   c.instanceURL(),
+		BackupId: backupID,
+		Backup: &databasepb.Backup{
+			Database:   c.config.URL(),
+			ExpireTime: timestamppb.New(expireTime),
+		},
+	}
+
+	op, err := c.spannerAdminClient.CreateBackup(ctx, req)
+	if err != nil {
+		return &Error{
+			Code: ErrorCodeCreateBackup,
+			err:  err,
+		}
+	}
+
+	if _, err := op.Wait(ctx); err != nil {
+		return &Error{
+			Code: ErrorCodeWaitOperation,
+			err:  err,
+		}
+	}
+
+	return nil
+}
+
+// RestoreDatabase restores backupID into a database named c.config.Database.
+// Per the Spanner API, that database must not already exist: callers
+// restoring over a live database (e.g. MigrateWithBackup) must drop it
+// first.
+func (c *Client) RestoreDatabase(ctx context.Context, backupID string) error {
+	req := &databasepb.RestoreDatabaseRequest{
+		Parent:     c.instanceURL(),
+		DatabaseId: c.config.Database,
+		Source: &databasepb.RestoreDatabaseRequest_Backup{
+			Backup: c.backupURL(backupID),
+		},
+	}
+
+	op, err := c.spannerAdminClient.RestoreDatabase(ctx, req)
+	if err != nil {
+		return &Error{
+			Code: ErrorCodeRestoreDatabase,
+			err:  err,
+		}
+	}
+
+	if _, err := op.Wait(ctx); err != nil {
+		return &Error{
+			Code: ErrorCodeWaitOperation,
+			err:  err,
+		}
+	}
+
+	return nil
+}
+
+// ListBackups returns every backup taken of the instance's databases.
+func (c *Client) ListBackups(ctx context.Context) ([]*databasepb.Backup, error) {
+	it := c.spannerAdminClient.ListBackups(ctx, &databasepb.ListBackupsRequest{
+		Parent: c.instanceURL(),
+	})
+
+	var backups []*databasepb.Backup
+	for {
+		b, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, &Error{
+				Code: ErrorCodeListBackups,
+				err:  err,
+			}
+		}
+		backups = append(backups, b)
+	}
+
+	return backups, nil
+}
+
+// MigrateWithBackup takes a backup of the database, then runs
+// ExecuteMigrations. If the migration fails and Config.RestoreOnMigrateFailure
+// is set, it automatically restores from the backup it just took before
+// returning the migration error.
+func (c *Client) MigrateWithBackup(ctx context.Context, migrations Migrations, limit int, tableName string) error {
+	backupID := fmt.Sprintf("wrench-premigrate-%d", time.Now().Unix())
+
+	if err := c.CreateBackup(ctx, backupID, time.Now().Add(c.backupExpiry())); err != nil {
+		return err
+	}
+
+	migrateErr := c.ExecuteMigrations(ctx, migrations, limit, tableName)
+	if migrateErr == nil {
+		return nil
+	}
+
+	if !c.config.RestoreOnMigrateFailure {
+		return migrateErr
+	}
+
+	// RestoreDatabase requires the target database not already exist, so
+	// the failed database has to be dropped before restoring over it.
+	if err := c.DropDatabase(ctx); err != nil {
+		return &Error{
+			Code: ErrorCodeRestoreDatabase,
+			err:  fmt.Errorf("migration failed (%s) and dropping the database before restore also failed: %w", migrateErr, err),
+		}
+	}
+
+	if err := c.RestoreDatabase(ctx, backupID); err != nil {
+		return &Error{
+			Code: ErrorCodeRestoreDatabase,
+			err:  fmt.Errorf("migration failed (%s) and restore from backup %q also failed: %w", migrateErr, backupID, err),
+		}
+	}
+
+	return migrateErr
+}
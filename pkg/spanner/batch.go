@@ -0,0 +1,158 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	databasepb "google.golang.org/genproto/googleapis/spanner/admin/database/v1"
+)
+
+// ddlRunLength returns how many migrations at the front of pending are
+// consecutive statementKindDDL migrations, i.e. how many can be
+// coalesced into a single applyDDLBatch call. It returns 0 if pending is
+// empty or doesn't start with a DDL migration.
+func ddlRunLength(pending Migrations) int {
+	if len(pending) == 0 || pending[0].kind != statementKindDDL {
+		return 0
+	}
+
+	n := 1
+	for n < len(pending) && pending[n].kind == statementKindDDL {
+		n++
+	}
+	return n
+}
+
+// applyDDLBatch coalesces a run of consecutive statementKindDDL migrations
+// into a single UpdateDatabaseDdl operation, which is significantly
+// faster than issuing one such operation per migration since each one can
+// take tens of seconds on Spanner.
+//
+// The batch's last Version is marked dirty up front, since SchemaMigrations
+// only tracks one version/dirty pair at a time. UpdateDatabaseDdl is not
+// atomic across statements, so a failure partway through a batch may have
+// already applied a prefix of it. applyDDLBatch inspects the operation's
+// metadata to work out how many leading migrations' statements actually
+// committed, records that prefix as the dirty version, and reports the
+// rest as failed, so Force is pointed at the version that actually needs
+// to be reconciled with the live schema rather than blindly unblocking
+// the whole batch.
+func (c *Client) applyDDLBatch(ctx context.Context, batch Migrations, tableName string, observer MigrationObserver) error {
+	last := batch[len(batch)-1]
+
+	if err := c.SetSchemaMigrationVersion(ctx, last.Version, true, tableName); err != nil {
+		return err
+	}
+
+	var statements []string
+	counts := make([]int, len(batch))
+	for i, m := range batch {
+		observer.OnMigrationStart(m.Version, m.Name, m.kind, MigrationDirectionUp)
+		statements = append(statements, m.Statements...)
+		counts[i] = len(m.Statements)
+	}
+
+	start := time.Now()
+	op, err := c.spannerAdminClient.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
+		Database:   c.config.URL(),
+		Statements: statements,
+	})
+	if err != nil {
+		for _, m := range batch {
+			observer.OnMigrationError(m.Version, m.Name, MigrationDirectionUp, err)
+		}
+		return &Error{
+			Code: ErrorCodeUpdateDDL,
+			err:  err,
+		}
+	}
+
+	waitErr := op.Wait(ctx)
+	duration := time.Since(start)
+
+	meta, _ := op.Metadata()
+	completed := appliedMigrationCount(counts, appliedStatementCount(meta))
+
+	for i := 0; i < completed; i++ {
+		observer.OnMigrationComplete(batch[i].Version, batch[i].Name, MigrationDirectionUp, duration, 0)
+	}
+
+	if waitErr != nil {
+		for i := completed; i < len(batch); i++ {
+			observer.OnMigrationError(batch[i].Version, batch[i].Name, MigrationDirectionUp, waitErr)
+		}
+
+		if completed == 0 {
+			return &Error{
+				Code: ErrorCodeExecuteMigrations,
+				err:  fmt.Errorf("batched DDL migration for versions %d-%d failed before any statement committed: %w", batch[0].Version, last.Version, waitErr),
+			}
+		}
+
+		confirmed := batch[completed-1].Version
+		if err := c.SetSchemaMigrationVersion(ctx, confirmed, true, tableName); err != nil {
+			return err
+		}
+
+		return &Error{
+			Code: ErrorCodeExecuteMigrations,
+			err:  fmt.Errorf("batched DDL migration for versions %d-%d failed after committing up to version %d; verify the schema and run Force(%d) to continue: %w", batch[0].Version, last.Version, confirmed, confirmed, waitErr),
+		}
+	}
+
+	return c.SetSchemaMigrationVersion(ctx, last.Version, false, tableName)
+}
+
+// appliedMigrationCount maps a count of successfully committed DDL
+// statements back to how many leading migrations in the batch are fully
+// accounted for, given each migration's statement count.
+func appliedMigrationCount(counts []int, appliedStatements int) int {
+	completed := 0
+	sum := 0
+	for _, n := range counts {
+		sum += n
+		if appliedStatements < sum {
+			break
+		}
+		completed++
+	}
+	return completed
+}
+
+// appliedStatementCount returns how many leading statements of an
+// UpdateDatabaseDdl operation have a commit timestamp, which Spanner
+// fills in order as each statement completes.
+func appliedStatementCount(meta *databasepb.UpdateDatabaseDdlMetadata) int {
+	if meta == nil {
+		return 0
+	}
+
+	n := 0
+	for _, ts := range meta.CommitTimestamps {
+		if ts == nil {
+			break
+		}
+		n++
+	}
+	return n
+}
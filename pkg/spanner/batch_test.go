@@ -0,0 +1,101 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import (
+	"testing"
+
+	databasepb "google.golang.org/genproto/googleapis/spanner/admin/database/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestDDLRunLength(t *testing.T) {
+	ddl := func(v uint) *Migration { return &Migration{Version: v, kind: statementKindDDL} }
+	dml := func(v uint) *Migration { return &Migration{Version: v, kind: statementKindDML} }
+
+	cases := []struct {
+		name    string
+		pending Migrations
+		want    int
+	}{
+		{"all ddl", Migrations{ddl(1), ddl(2), ddl(3)}, 3},
+		{"ddl run broken by dml", Migrations{ddl(1), ddl(2), dml(3), ddl(4)}, 2},
+		{"starts with dml", Migrations{dml(1), ddl(2)}, 0},
+		{"single migration", Migrations{ddl(1)}, 1},
+		{"empty", Migrations{}, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ddlRunLength(tc.pending); got != tc.want {
+				t.Errorf("ddlRunLength() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAppliedStatementCount(t *testing.T) {
+	ts := timestamppb.Now()
+
+	cases := []struct {
+		name string
+		meta *databasepb.UpdateDatabaseDdlMetadata
+		want int
+	}{
+		{"nil metadata", nil, 0},
+		{"none committed", &databasepb.UpdateDatabaseDdlMetadata{CommitTimestamps: []*timestamppb.Timestamp{nil, nil}}, 0},
+		{"partial", &databasepb.UpdateDatabaseDdlMetadata{CommitTimestamps: []*timestamppb.Timestamp{ts, ts, nil}}, 2},
+		{"all committed", &databasepb.UpdateDatabaseDdlMetadata{CommitTimestamps: []*timestamppb.Timestamp{ts, ts, ts}}, 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := appliedStatementCount(tc.meta); got != tc.want {
+				t.Errorf("appliedStatementCount() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAppliedMigrationCount(t *testing.T) {
+	// Three migrations with 2, 1, and 3 statements respectively.
+	counts := []int{2, 1, 3}
+
+	cases := []struct {
+		name       string
+		statements int
+		want       int
+	}{
+		{"none applied", 0, 0},
+		{"mid-statement in first migration", 1, 0},
+		{"exactly first migration", 2, 1},
+		{"exactly first two migrations", 3, 2},
+		{"mid-statement in last migration", 4, 2},
+		{"all migrations", 6, 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := appliedMigrationCount(counts, tc.statements); got != tc.want {
+				t.Errorf("appliedMigrationCount() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
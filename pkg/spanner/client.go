@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"time"
 
 	"cloud.google.com/go/spanner"
 	admin "cloud.google.com/go/spanner/admin/database/apiv1"
@@ -140,7 +141,19 @@ func (c *Client) LoadDDL(ctx context.Context) ([]byte, error) {
 }
 
 func (c *Client) ApplyDDLFile(ctx context.Context, ddl []byte) error {
-	return c.ApplyDDL(ctx, toStatements(ddl))
+	if !c.config.CleanStatements {
+		return c.ApplyDDL(ctx, toStatements(ddl))
+	}
+
+	statements, err := cleanDDLStatements(ddl)
+	if err != nil {
+		return &Error{
+			Code: ErrorCodeParseDDL,
+			err:  err,
+		}
+	}
+
+	return c.ApplyDDL(ctx, statements)
 }
 
 func (c *Client) ApplyDDL(ctx context.Context, statements []string) error {
@@ -169,7 +182,12 @@ func (c *Client) ApplyDDL(ctx context.Context, statements []string) error {
 }
 
 func (c *Client) ApplyDMLFile(ctx context.Context, ddl []byte, partitioned bool) (int64, error) {
-	statements := toStatements(ddl)
+	var statements []string
+	if c.config.CleanStatements {
+		statements = cleanDMLStatements(ddl)
+	} else {
+		statements = toStatements(ddl)
+	}
 
 	if partitioned {
 		return c.ApplyPartitionedDML(ctx, statements)
@@ -222,6 +240,20 @@ func (c *Client) ApplyPartitionedDML(ctx context.Context, statements []string) (
 }
 
 func (c *Client) ExecuteMigrations(ctx context.Context, migrations Migrations, limit int, tableName string) error {
+	observer := c.observer()
+
+	if err := c.Lock(ctx); err != nil {
+		return &Error{
+			Code: ErrorCodeExecuteMigrations,
+			err:  err,
+		}
+	}
+	observer.OnLockAcquired()
+	defer func() {
+		c.Unlock(ctx)
+		observer.OnLockReleased()
+	}()
+
 	sort.Sort(migrations)
 
 	version, dirty, err := c.GetSchemaMigrationVersion(ctx, tableName)
@@ -242,69 +274,86 @@ func (c *Client) ExecuteMigrations(ctx context.Context, migrations Migrations, l
 		}
 	}
 
-	var count int
-	for _, m := range migrations {
-		if limit == 0 {
-			break
-		}
+	if limit == 0 {
+		fmt.Println("no change")
+		return nil
+	}
 
+	var pending Migrations
+	for _, m := range migrations {
 		if m.Version <= version {
 			continue
 		}
-
-		if err := c.SetSchemaMigrationVersion(ctx, m.Version, true, tableName); err != nil {
-			return &Error{
-				Code: ErrorCodeExecuteMigrations,
-				err:  err,
-			}
+		pending = append(pending, m)
+		if limit > 0 && len(pending) == limit {
+			break
 		}
+	}
 
-		switch m.kind {
-		case statementKindDDL:
-			if err := c.ApplyDDL(ctx, m.Statements); err != nil {
-				return &Error{
-					Code: ErrorCodeExecuteMigrations,
-					err:  err,
-				}
-			}
-		case statementKindDML:
-			if _, err := c.ApplyPartitionedDML(ctx, m.Statements); err != nil {
+	if len(pending) == 0 {
+		fmt.Println("no change")
+		return nil
+	}
+
+	for len(pending) > 0 {
+		if c.config.BatchDDLMigrations && pending[0].kind == statementKindDDL {
+			n := ddlRunLength(pending)
+			if err := c.applyDDLBatch(ctx, pending[:n], tableName, observer); err != nil {
 				return &Error{
 					Code: ErrorCodeExecuteMigrations,
 					err:  err,
 				}
 			}
-		default:
-			return &Error{
-				Code: ErrorCodeExecuteMigrations,
-				err:  fmt.Errorf("Unknown query type, version: %d", m.Version),
-			}
-		}
-
-		if m.Name != "" {
-			fmt.Printf("%d/up %s\n", m.Version, m.Name)
-		} else {
-			fmt.Printf("%d/up\n", m.Version)
+			pending = pending[n:]
+			continue
 		}
 
-		if err := c.SetSchemaMigrationVersion(ctx, m.Version, false, tableName); err != nil {
+		if err := c.applyMigration(ctx, pending[0], tableName, observer); err != nil {
 			return &Error{
 				Code: ErrorCodeExecuteMigrations,
 				err:  err,
 			}
 		}
+		pending = pending[1:]
+	}
 
-		count++
-		if limit > 0 && count == limit {
-			break
-		}
+	return nil
+}
+
+// applyMigration runs a single migration's up statements, following the
+// dirty-flag protocol: mark dirty, apply, clear dirty and advance the
+// recorded version.
+func (c *Client) applyMigration(ctx context.Context, m *Migration, tableName string, observer MigrationObserver) error {
+	if err := c.SetSchemaMigrationVersion(ctx, m.Version, true, tableName); err != nil {
+		return err
 	}
 
-	if count == 0 {
-		fmt.Println("no change")
+	observer.OnMigrationStart(m.Version, m.Name, m.kind, MigrationDirectionUp)
+	start := time.Now()
+
+	var rowsAffected int64
+	switch m.kind {
+	case statementKindDDL:
+		if err := c.ApplyDDL(ctx, m.Statements); err != nil {
+			observer.OnMigrationError(m.Version, m.Name, MigrationDirectionUp, err)
+			return err
+		}
+	case statementKindDML:
+		num, err := c.ApplyPartitionedDML(ctx, m.Statements)
+		if err != nil {
+			observer.OnMigrationError(m.Version, m.Name, MigrationDirectionUp, err)
+			return err
+		}
+		rowsAffected = num
+	default:
+		err := fmt.Errorf("Unknown query type, version: %d", m.Version)
+		observer.OnMigrationError(m.Version, m.Name, MigrationDirectionUp, err)
+		return err
 	}
 
-	return nil
+	observer.OnMigrationComplete(m.Version, m.Name, MigrationDirectionUp, time.Since(start), rowsAffected)
+
+	return c.SetSchemaMigrationVersion(ctx, m.Version, false, tableName)
 }
 
 func (c *Client) GetSchemaMigrationVersion(ctx context.Context, tableName string) (uint, bool, error) {
@@ -0,0 +1,67 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config is the configuration needed to create a Client.
+type Config struct {
+	Project         string
+	Instance        string
+	Database        string
+	CredentialsFile string
+
+	// LockTTL bounds how long a migration lock may be held before another
+	// caller is allowed to break it as stale. Zero means defaultLockTTL.
+	LockTTL time.Duration
+
+	// CleanStatements parses migration DDL through spansql instead of
+	// naively splitting on ";", so semicolons inside string literals,
+	// comments, and multi-line OPTIONS(...) blocks are handled correctly.
+	// DML is still split on ";" first and each piece is then normalized
+	// through spansql; if any piece fails to parse, the plain split is used.
+	CleanStatements bool
+
+	// BackupExpiry sets how long a backup taken by MigrateWithBackup is
+	// kept before Spanner garbage-collects it. Zero means defaultBackupExpiry.
+	BackupExpiry time.Duration
+
+	// RestoreOnMigrateFailure, when set, makes MigrateWithBackup restore
+	// the database from the backup it took if ExecuteMigrations fails.
+	RestoreOnMigrateFailure bool
+
+	// Observer receives structured migration events instead of the
+	// default stdout output. Nil uses the default stdout implementation.
+	Observer MigrationObserver
+
+	// BatchDDLMigrations coalesces consecutive DDL migrations into a
+	// single UpdateDatabaseDdl operation, rather than issuing one such
+	// operation per migration.
+	BatchDDLMigrations bool
+}
+
+// URL returns the fully qualified Cloud Spanner database path used by the
+// Spanner client and admin APIs.
+func (c *Config) URL() string {
+	return fmt.Sprintf("projects/%s/instances/%s/databases/%s", c.Project, c.Instance, c.Database)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import "fmt"
+
+// ErrorCode identifies the class of failure an Error represents.
+type ErrorCode int
+
+const (
+	ErrorCodeCreateClient ErrorCode = iota
+	ErrorCodeCreateDatabase
+	ErrorCodeWaitOperation
+	ErrorCodeDropDatabase
+	ErrorCodeLoadSchema
+	ErrorCodeUpdateDDL
+	ErrorCodeUpdateDML
+	ErrorCodeUpdatePartitionedDML
+	ErrorCodeExecuteMigrations
+	ErrorCodeNoMigration
+	ErrorCodeMigrationVersionDirty
+	ErrorCodeGetMigrationVersion
+	ErrorCodeSetMigrationVersion
+	ErrorCodeCloseClient
+	ErrorCodeLockHeld
+	ErrorCodeLockNotHeld
+	ErrorCodeAcquireLock
+	ErrorCodeReleaseLock
+	ErrorCodeParseDDL
+	ErrorCodeCreateBackup
+	ErrorCodeRestoreDatabase
+	ErrorCodeListBackups
+)
+
+// Error wraps an underlying error with a Code identifying what operation
+// failed, so callers can branch on failure class without string matching.
+type Error struct {
+	Code ErrorCode
+	err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("code: %d, err: %s", e.Code, e.err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
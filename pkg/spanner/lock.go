@@ -0,0 +1,159 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"google.golang.org/grpc/codes"
+)
+
+// errLockHeld is an internal sentinel used to unwind a ReadWriteTransaction
+// when the lock row is present and has not yet expired.
+var errLockHeld = errors.New("migration lock held")
+
+const (
+	lockTableName = "SchemaMigrationsLock"
+
+	// lockID is the single row identifier used to serialize migrations
+	// across every caller of a given database. wrench only ever needs one
+	// concurrent migration lock, so a single well-known row is sufficient.
+	lockID = "wrench"
+
+	// defaultLockTTL bounds how long a lock can be held before it is
+	// considered stale and can be broken by another runner, so a crashed
+	// process doesn't wedge migrations forever.
+	defaultLockTTL = 15 * time.Minute
+)
+
+// lockTTL returns the configured lock TTL, or defaultLockTTL if unset.
+func (c *Client) lockTTL() time.Duration {
+	if c.config.LockTTL > 0 {
+		return c.config.LockTTL
+	}
+	return defaultLockTTL
+}
+
+// EnsureLockTable creates the migration lock table if it does not already
+// exist, mirroring EnsureMigrationTable's bootstrapping behavior.
+func (c *Client) EnsureLockTable(ctx context.Context) error {
+	iter := c.spannerClient.Single().Read(ctx, lockTableName, spanner.AllKeys(), []string{"LockID"})
+	err := iter.Do(func(r *spanner.Row) error {
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+
+	stmt := fmt.Sprintf(`CREATE TABLE %s (
+    LockID STRING(MAX) NOT NULL,
+    Expiry TIMESTAMP
+	) PRIMARY KEY(LockID)`, lockTableName)
+
+	return c.ApplyDDL(ctx, []string{stmt})
+}
+
+// Lock acquires an exclusive migration lock, bootstrapping the lock table
+// if necessary. A lock held past its TTL is considered abandoned and is
+// broken automatically, so a crashed CI runner or pod doesn't block
+// migrations indefinitely.
+func (c *Client) Lock(ctx context.Context) error {
+	if err := c.EnsureLockTable(ctx); err != nil {
+		return &Error{
+			Code: ErrorCodeAcquireLock,
+			err:  err,
+		}
+	}
+
+	expiry := time.Now().Add(c.lockTTL())
+
+	_, err := c.spannerClient.ReadWriteTransaction(ctx, func(ctx context.Context, tx *spanner.ReadWriteTransaction) error {
+		row, err := tx.ReadRow(ctx, lockTableName, spanner.Key{lockID}, []string{"Expiry"})
+		if err != nil && spanner.ErrCode(err) != codes.NotFound {
+			return err
+		}
+
+		if err == nil {
+			var current time.Time
+			if err := row.Column(0, &current); err != nil {
+				return err
+			}
+			if time.Now().Before(current) {
+				return errLockHeld
+			}
+		}
+
+		return tx.BufferWrite([]*spanner.Mutation{
+			spanner.InsertOrUpdate(
+				lockTableName,
+				[]string{"LockID", "Expiry"},
+				[]interface{}{lockID, expiry},
+			),
+		})
+	})
+	if err == errLockHeld {
+		return &Error{
+			Code: ErrorCodeLockHeld,
+			err:  fmt.Errorf("migration lock is currently held, try again later"),
+		}
+	}
+	if err != nil {
+		return &Error{
+			Code: ErrorCodeAcquireLock,
+			err:  err,
+		}
+	}
+
+	return nil
+}
+
+// Unlock releases the migration lock. It returns ErrorCodeLockNotHeld if
+// no lock is currently held, and ErrorCodeReleaseLock for any other,
+// unrelated failure (so callers don't mistake a transport error for a
+// missing lock).
+func (c *Client) Unlock(ctx context.Context) error {
+	_, err := c.spannerClient.ReadWriteTransaction(ctx, func(ctx context.Context, tx *spanner.ReadWriteTransaction) error {
+		_, err := tx.ReadRow(ctx, lockTableName, spanner.Key{lockID}, []string{"LockID"})
+		if err != nil {
+			return err
+		}
+		return tx.BufferWrite([]*spanner.Mutation{
+			spanner.Delete(lockTableName, spanner.Key{lockID}),
+		})
+	})
+	if spanner.ErrCode(err) == codes.NotFound {
+		return &Error{
+			Code: ErrorCodeLockNotHeld,
+			err:  fmt.Errorf("no migration lock is currently held"),
+		}
+	}
+	if err != nil {
+		return &Error{
+			Code: ErrorCodeReleaseLock,
+			err:  err,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import "strings"
+
+// statementKind distinguishes how a migration's statements must be applied.
+type statementKind int
+
+const (
+	statementKindDDL statementKind = iota
+	statementKindDML
+)
+
+// Migration represents a single versioned migration, combining the
+// forward (*.up.sql) and, when present, the reverse (*.down.sql)
+// statements for that version.
+type Migration struct {
+	Version        uint
+	Name           string
+	kind           statementKind
+	Statements     []string
+	DownStatements []string
+}
+
+// Migrations is a sortable collection of Migration, ordered by Version.
+type Migrations []*Migration
+
+func (m Migrations) Len() int {
+	return len(m)
+}
+
+func (m Migrations) Swap(i, j int) {
+	m[i], m[j] = m[j], m[i]
+}
+
+func (m Migrations) Less(i, j int) bool {
+	return m[i].Version < m[j].Version
+}
+
+// toStatements splits a raw DDL/DML file into individual statements,
+// trimming whitespace and dropping empty entries.
+func toStatements(ddl []byte) []string {
+	ss := strings.Split(string(ddl), ddlStatementsSeparator)
+
+	statements := make([]string, 0, len(ss))
+	for _, s := range ss {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		statements = append(statements, s)
+	}
+
+	return statements
+}
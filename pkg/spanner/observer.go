@@ -0,0 +1,87 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import (
+	"fmt"
+	"time"
+)
+
+// MigrationDirection distinguishes a forward migration step from a
+// rollback, so observers don't have to infer it from call order.
+type MigrationDirection int
+
+const (
+	MigrationDirectionUp MigrationDirection = iota
+	MigrationDirectionDown
+)
+
+// MigrationObserver receives structured events as ExecuteMigrations and
+// RollbackMigrations run, so callers embedding wrench as a library, or
+// shipping logs to a structured backend, aren't limited to the CLI's
+// stdout output. version and name are passed to every callback (rather
+// than cached from OnMigrationStart) so implementations stay correct
+// when several migrations are batched together.
+type MigrationObserver interface {
+	OnMigrationStart(version uint, name string, kind statementKind, direction MigrationDirection)
+	OnMigrationComplete(version uint, name string, direction MigrationDirection, duration time.Duration, rowsAffected int64)
+	OnMigrationError(version uint, name string, direction MigrationDirection, err error)
+	OnLockAcquired()
+	OnLockReleased()
+}
+
+// stdoutObserver is the default MigrationObserver, preserving wrench's
+// historical fmt.Printf-based CLI output.
+type stdoutObserver struct{}
+
+func (stdoutObserver) OnMigrationStart(version uint, name string, kind statementKind, direction MigrationDirection) {
+}
+
+func (stdoutObserver) OnMigrationComplete(version uint, name string, direction MigrationDirection, duration time.Duration, rowsAffected int64) {
+	verb := directionVerb(direction)
+	if name != "" {
+		fmt.Printf("%d/%s %s\n", version, verb, name)
+	} else {
+		fmt.Printf("%d/%s\n", version, verb)
+	}
+}
+
+func (stdoutObserver) OnMigrationError(version uint, name string, direction MigrationDirection, err error) {
+	fmt.Printf("%d/%s failed: %s\n", version, directionVerb(direction), err)
+}
+
+func (stdoutObserver) OnLockAcquired() {}
+func (stdoutObserver) OnLockReleased() {}
+
+func directionVerb(direction MigrationDirection) string {
+	if direction == MigrationDirectionDown {
+		return "down"
+	}
+	return "up"
+}
+
+// observer returns the configured MigrationObserver, or the default
+// stdout implementation if none was set.
+func (c *Client) observer() MigrationObserver {
+	if c.config.Observer != nil {
+		return c.config.Observer
+	}
+	return stdoutObserver{}
+}
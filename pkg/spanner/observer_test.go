@@ -0,0 +1,93 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	fn()
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+// TestStdoutObserverBatchKeepsPerMigrationNames guards against the
+// regression where a batch of OnMigrationStart calls followed by a
+// batch of OnMigrationComplete calls caused every migration to report
+// the last migration's name, because the name was cached on the
+// observer instead of passed to OnMigrationComplete directly.
+func TestStdoutObserverBatchKeepsPerMigrationNames(t *testing.T) {
+	o := stdoutObserver{}
+	batch := []*Migration{
+		{Version: 2, Name: "foo", kind: statementKindDDL},
+		{Version: 3, Name: "bar", kind: statementKindDDL},
+		{Version: 4, Name: "baz", kind: statementKindDDL},
+	}
+
+	out := captureStdout(t, func() {
+		for _, m := range batch {
+			o.OnMigrationStart(m.Version, m.Name, m.kind, MigrationDirectionUp)
+		}
+		for _, m := range batch {
+			o.OnMigrationComplete(m.Version, m.Name, MigrationDirectionUp, time.Millisecond, 0)
+		}
+	})
+
+	want := "2/up foo\n3/up bar\n4/up baz\n"
+	if out != want {
+		t.Errorf("batched output = %q, want %q", out, want)
+	}
+}
+
+func TestStdoutObserverDirectionVerb(t *testing.T) {
+	o := stdoutObserver{}
+
+	out := captureStdout(t, func() {
+		o.OnMigrationComplete(5, "", MigrationDirectionDown, time.Millisecond, 0)
+	})
+
+	if !strings.HasPrefix(out, "5/down") {
+		t.Errorf("output = %q, want it to start with %q", out, "5/down")
+	}
+}
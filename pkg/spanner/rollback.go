@@ -0,0 +1,193 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/spanner"
+)
+
+// RollbackMigrations walks migrations backwards from the current schema
+// version, applying each Migration's DownStatements and decrementing the
+// recorded version in tableName down to the previous migration's version
+// (or deleting the row entirely once every migration has been rolled
+// back). limit follows ExecuteMigrations' convention: 0 applies nothing,
+// a positive value applies at most that many steps, and a negative value
+// rolls back everything.
+func (c *Client) RollbackMigrations(ctx context.Context, migrations Migrations, limit int, tableName string) error {
+	observer := c.observer()
+
+	if err := c.Lock(ctx); err != nil {
+		return &Error{
+			Code: ErrorCodeExecuteMigrations,
+			err:  err,
+		}
+	}
+	observer.OnLockAcquired()
+	defer func() {
+		c.Unlock(ctx)
+		observer.OnLockReleased()
+	}()
+
+	sort.Sort(sort.Reverse(migrations))
+
+	version, dirty, err := c.GetSchemaMigrationVersion(ctx, tableName)
+	if err != nil {
+		var se *Error
+		if !errors.As(err, &se) || se.Code != ErrorCodeNoMigration {
+			return &Error{
+				Code: ErrorCodeExecuteMigrations,
+				err:  err,
+			}
+		}
+	}
+
+	if dirty {
+		return &Error{
+			Code: ErrorCodeMigrationVersionDirty,
+			err:  fmt.Errorf("Database version: %d is dirty, please fix it.", version),
+		}
+	}
+
+	var count int
+	for i, m := range migrations {
+		if limit == 0 {
+			break
+		}
+
+		if m.Version > version {
+			continue
+		}
+
+		if len(m.DownStatements) == 0 {
+			return &Error{
+				Code: ErrorCodeExecuteMigrations,
+				err:  fmt.Errorf("no down migration for version: %d", m.Version),
+			}
+		}
+
+		if err := c.SetSchemaMigrationVersion(ctx, m.Version, true, tableName); err != nil {
+			return &Error{
+				Code: ErrorCodeExecuteMigrations,
+				err:  err,
+			}
+		}
+
+		observer.OnMigrationStart(m.Version, m.Name, m.kind, MigrationDirectionDown)
+		start := time.Now()
+
+		var rowsAffected int64
+		switch m.kind {
+		case statementKindDDL:
+			if err := c.ApplyDDL(ctx, m.DownStatements); err != nil {
+				observer.OnMigrationError(m.Version, m.Name, MigrationDirectionDown, err)
+				return &Error{
+					Code: ErrorCodeExecuteMigrations,
+					err:  err,
+				}
+			}
+		case statementKindDML:
+			num, err := c.ApplyPartitionedDML(ctx, m.DownStatements)
+			if err != nil {
+				observer.OnMigrationError(m.Version, m.Name, MigrationDirectionDown, err)
+				return &Error{
+					Code: ErrorCodeExecuteMigrations,
+					err:  err,
+				}
+			}
+			rowsAffected = num
+		default:
+			err := fmt.Errorf("Unknown query type, version: %d", m.Version)
+			observer.OnMigrationError(m.Version, m.Name, MigrationDirectionDown, err)
+			return &Error{
+				Code: ErrorCodeExecuteMigrations,
+				err:  err,
+			}
+		}
+
+		observer.OnMigrationComplete(m.Version, m.Name, MigrationDirectionDown, time.Since(start), rowsAffected)
+
+		var previous uint
+		for _, prev := range migrations[i+1:] {
+			if prev.Version < m.Version {
+				previous = prev.Version
+				break
+			}
+		}
+
+		if previous == 0 {
+			if err := c.deleteSchemaMigrationVersion(ctx, tableName); err != nil {
+				return &Error{
+					Code: ErrorCodeExecuteMigrations,
+					err:  err,
+				}
+			}
+		} else if err := c.SetSchemaMigrationVersion(ctx, previous, false, tableName); err != nil {
+			return &Error{
+				Code: ErrorCodeExecuteMigrations,
+				err:  err,
+			}
+		}
+
+		version = previous
+		count++
+		if limit > 0 && count == limit {
+			break
+		}
+	}
+
+	if count == 0 {
+		fmt.Println("no change")
+	}
+
+	return nil
+}
+
+// deleteSchemaMigrationVersion removes the single row in tableName,
+// used once RollbackMigrations has walked back past the earliest
+// recorded version.
+func (c *Client) deleteSchemaMigrationVersion(ctx context.Context, tableName string) error {
+	_, err := c.spannerClient.ReadWriteTransaction(ctx, func(ctx context.Context, tx *spanner.ReadWriteTransaction) error {
+		return tx.BufferWrite([]*spanner.Mutation{
+			spanner.Delete(tableName, spanner.AllKeys()),
+		})
+	})
+	if err != nil {
+		return &Error{
+			Code: ErrorCodeSetMigrationVersion,
+			err:  err,
+		}
+	}
+
+	return nil
+}
+
+// Force clears a dirty flag left behind by a failed migration, setting
+// tableName's recorded version directly without applying any statements.
+// This mirrors the manual recovery step familiar from other migration
+// tools: fix the schema by hand, then force the version to match.
+func (c *Client) Force(ctx context.Context, version uint, tableName string) error {
+	return c.SetSchemaMigrationVersion(ctx, version, false, tableName)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2020 Mercari, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package spanner
+
+import "cloud.google.com/go/spanner/spansql"
+
+// cleanDDLStatements splits a DDL file into normalized, comment-stripped
+// statements using spansql, which correctly handles semicolons inside
+// string literals, comments, and multi-line OPTIONS(...) blocks. The
+// naive separator-based split in toStatements gets all of those wrong.
+//
+// Parse failures are returned to the caller rather than silently falling
+// back, since a user who opted into CleanStatements wants to know their
+// migration file is malformed before it is sent to Spanner.
+func cleanDDLStatements(raw []byte) ([]string, error) {
+	ddl, err := spansql.ParseDDL("", string(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	statements := make([]string, 0, len(ddl.List))
+	for _, stmt := range ddl.List {
+		statements = append(statements, stmt.SQL())
+	}
+
+	return statements, nil
+}
+
+// cleanDMLStatements is the DML counterpart of cleanDDLStatements. spansql's
+// DML grammar coverage is less complete than its DDL coverage, so unlike
+// ApplyDDLFile a parse failure here falls back to the naive splitter
+// instead of failing the caller outright.
+func cleanDMLStatements(raw []byte) []string {
+	candidates := toStatements(raw)
+
+	statements := make([]string, 0, len(candidates))
+	for _, s := range candidates {
+		stmt, err := spansql.ParseDMLStmt(s)
+		if err != nil {
+			return candidates
+		}
+		statements = append(statements, stmt.SQL())
+	}
+
+	return statements
+}